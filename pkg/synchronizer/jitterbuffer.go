@@ -0,0 +1,184 @@
+package synchronizer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	jitterBufferSize = 2048 // ring buffer size, indexed by seq % jitterBufferSize
+	nackBitmapBits   = 16   // bits available in a NACK follow-up bitmap
+)
+
+// jitterBufferEntry holds a single buffered packet, keyed by its (possibly reordered) sequence number
+type jitterBufferEntry struct {
+	seq       uint16
+	ts        uint32
+	payload   *rtp.Packet
+	arrivedAt int64 // unix nanoseconds, used to age packets out of the buffer
+}
+
+// jitterBuffer reorders incoming RTP packets for a single SSRC, holding them for up to depth
+// before releasing them in sequence-number order. Gaps that are still outstanding when a packet
+// ages out of the buffer are treated as true losses rather than reordering, and skipped.
+type jitterBuffer struct {
+	ssrc  uint32
+	depth time.Duration
+
+	ring [jitterBufferSize]*jitterBufferEntry
+
+	initialized bool
+	nextSeq     uint16 // next sequence number we expect to release
+
+	onNACK func(ssrc uint32, firstSeq uint16, bitmap uint16)
+}
+
+func newJitterBuffer(ssrc uint32, depth time.Duration) *jitterBuffer {
+	return &jitterBuffer{
+		ssrc:  ssrc,
+		depth: depth,
+	}
+}
+
+// push inserts a packet into the buffer. If the new packet is ahead of nextSeq, it builds a NACK
+// bitmap for the seqs in between that are still missing and reports it via onNACK. Packets behind
+// nextSeq (already released or skipped as lost) are dropped.
+func (b *jitterBuffer) push(pkt *rtp.Packet, now int64) {
+	seq := pkt.SequenceNumber
+
+	if !b.initialized {
+		b.initialized = true
+		b.nextSeq = seq
+	} else if int16(seq-b.nextSeq) < 0 {
+		return
+	}
+
+	b.ring[seq%jitterBufferSize] = &jitterBufferEntry{
+		seq:       seq,
+		ts:        pkt.Timestamp,
+		payload:   pkt,
+		arrivedAt: now,
+	}
+
+	if gap := int16(seq - b.nextSeq); gap > 0 {
+		b.reportMissing(seq, gap)
+	}
+}
+
+// reportMissing builds a NACK bitmap covering the seqs between nextSeq and seq (exclusive) that
+// have not already been received, and hands it to onNACK. nextSeq itself may already have arrived
+// (it's only released once it's popped), so the first missing seq has to be found by scanning.
+func (b *jitterBuffer) reportMissing(seq uint16, gap int16) {
+	if b.onNACK == nil {
+		return
+	}
+
+	var firstSeq uint16
+	found := false
+	for i := int16(0); i < gap; i++ {
+		candidate := b.nextSeq + uint16(i)
+		if b.ring[candidate%jitterBufferSize] == nil {
+			firstSeq = candidate
+			found = true
+			break
+		}
+	}
+	if !found {
+		// everything up to seq has already arrived; nothing missing to report
+		return
+	}
+
+	var bitmap uint16
+	for i := uint16(1); i <= nackBitmapBits; i++ {
+		followSeq := firstSeq + i
+		if followSeq == seq {
+			break
+		}
+		if b.ring[followSeq%jitterBufferSize] == nil {
+			bitmap |= 1 << (i - 1)
+		}
+	}
+
+	b.onNACK(b.ssrc, firstSeq, bitmap)
+}
+
+// pop releases every packet at the front of the buffer that is either ready (contiguous) or has
+// aged past depth, in which case the gap is treated as a genuine loss and skipped over.
+func (b *jitterBuffer) pop(now int64) []*rtp.Packet {
+	var out []*rtp.Packet
+
+	for {
+		entry := b.ring[b.nextSeq%jitterBufferSize]
+		if entry == nil || entry.seq != b.nextSeq {
+			// not yet received - only skip it once the oldest buffered packet has aged out,
+			// which tells us this seq is a real loss rather than a reordering in flight
+			oldest, found := b.oldestArrival()
+			if !found || now-oldest < int64(b.depth) {
+				break
+			}
+			b.nextSeq++
+			continue
+		}
+
+		if now-entry.arrivedAt < int64(b.depth) {
+			break
+		}
+
+		out = append(out, entry.payload)
+		b.ring[entry.seq%jitterBufferSize] = nil
+		b.nextSeq++
+	}
+
+	return out
+}
+
+// flush releases every packet still held in the buffer, in sequence-number order, regardless of
+// depth or outstanding gaps. Intended for end-of-stream, since nothing will arrive afterward to
+// drive a normal pop() and age the tail out.
+func (b *jitterBuffer) flush() []*rtp.Packet {
+	type held struct {
+		seq uint16
+		pkt *rtp.Packet
+	}
+
+	var entries []held
+	for i, e := range b.ring {
+		if e == nil {
+			continue
+		}
+		entries = append(entries, held{seq: e.seq, pkt: e.payload})
+		b.ring[i] = nil
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return int16(entries[i].seq-entries[j].seq) < 0
+	})
+
+	out := make([]*rtp.Packet, len(entries))
+	for i, e := range entries {
+		out[i] = e.pkt
+		b.nextSeq = e.seq + 1
+	}
+	return out
+}
+
+// oldestArrival returns the arrival time of the oldest packet still buffered, and whether the
+// buffer has anything queued at all. Every entry still in the ring is ahead of nextSeq (entries
+// are cleared as they're released), so the whole ring is scanned rather than some fixed lookahead
+// - a window would miss packets buffered further ahead than its size on a deep enough buffer.
+func (b *jitterBuffer) oldestArrival() (int64, bool) {
+	var oldest int64
+	found := false
+	for _, entry := range b.ring {
+		if entry == nil {
+			continue
+		}
+		if !found || entry.arrivedAt < oldest {
+			oldest = entry.arrivedAt
+			found = true
+		}
+	}
+	return oldest, found
+}
@@ -0,0 +1,103 @@
+package synchronizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+func pktWithSeq(seq uint16) *rtp.Packet {
+	return &rtp.Packet{Header: rtp.Header{SequenceNumber: seq}}
+}
+
+func TestJitterBufferReordersWithinDepth(t *testing.T) {
+	b := newJitterBuffer(1, 50*time.Millisecond)
+
+	now := int64(0)
+	b.push(pktWithSeq(1), now)
+	b.push(pktWithSeq(3), now) // arrives early, out of order
+	b.push(pktWithSeq(2), now) // fills the gap before depth elapses
+
+	// nothing should release yet; everything is younger than depth
+	if out := b.pop(now); len(out) != 0 {
+		t.Fatalf("expected no packets released before depth elapses, got %d", len(out))
+	}
+
+	// once depth has passed, all three should come out in order
+	out := b.pop(now + int64(60*time.Millisecond))
+	if len(out) != 3 {
+		t.Fatalf("expected 3 packets released, got %d", len(out))
+	}
+	for i, pkt := range out {
+		if want := uint16(i + 1); pkt.SequenceNumber != want {
+			t.Fatalf("packet %d: expected seq %d, got %d", i, want, pkt.SequenceNumber)
+		}
+	}
+}
+
+func TestJitterBufferReportsNACKOnGap(t *testing.T) {
+	b := newJitterBuffer(7, 50*time.Millisecond)
+
+	var gotSSRC uint32
+	var gotFirstSeq uint16
+	var gotBitmap uint16
+	b.onNACK = func(ssrc uint32, firstSeq uint16, bitmap uint16) {
+		gotSSRC = ssrc
+		gotFirstSeq = firstSeq
+		gotBitmap = bitmap
+	}
+
+	b.push(pktWithSeq(1), 0)
+	// seq 2 and 3 are missing, seq 4 arrives
+	b.push(pktWithSeq(4), 0)
+
+	if gotSSRC != 7 {
+		t.Fatalf("expected NACK for ssrc 7, got %d", gotSSRC)
+	}
+	if gotFirstSeq != 2 {
+		t.Fatalf("expected first missing seq 2, got %d", gotFirstSeq)
+	}
+	// seq 3 is the only other missing seq in range, bit 0 (seq firstSeq+1) should be set
+	if gotBitmap != 1<<0 {
+		t.Fatalf("expected bitmap 0b1, got %b", gotBitmap)
+	}
+}
+
+func TestJitterBufferTreatsStaleGapAsLoss(t *testing.T) {
+	b := newJitterBuffer(1, 50*time.Millisecond)
+
+	b.push(pktWithSeq(1), 0)
+	b.push(pktWithSeq(3), 0) // seq 2 never arrives
+
+	// seq 3 ages out without seq 2 ever showing up; it should still be released, skipping seq 2
+	out := b.pop(int64(60 * time.Millisecond))
+	if len(out) != 2 {
+		t.Fatalf("expected 2 packets released (seq 2 treated as lost), got %d", len(out))
+	}
+	if out[0].SequenceNumber != 1 || out[1].SequenceNumber != 3 {
+		t.Fatalf("unexpected sequence numbers released: %d, %d", out[0].SequenceNumber, out[1].SequenceNumber)
+	}
+}
+
+func TestJitterBufferFlushReleasesEverythingAtEOS(t *testing.T) {
+	b := newJitterBuffer(1, time.Hour) // depth long enough that a normal pop would never release these
+
+	b.push(pktWithSeq(1), 0)
+	b.push(pktWithSeq(2), 0)
+	b.push(pktWithSeq(4), 0) // seq 3 missing
+
+	if out := b.pop(0); len(out) != 0 {
+		t.Fatalf("expected nothing released by a normal pop before depth elapses, got %d", len(out))
+	}
+
+	out := b.flush()
+	if len(out) != 3 {
+		t.Fatalf("expected flush to release all 3 buffered packets, got %d", len(out))
+	}
+	for i, want := range []uint16{1, 2, 4} {
+		if out[i].SequenceNumber != want {
+			t.Fatalf("packet %d: expected seq %d, got %d", i, want, out[i].SequenceNumber)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package synchronizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/webrtc/v3"
+)
+
+func TestRequestKeyFrameEscalatesToFIRAfterTwoUnansweredPLIs(t *testing.T) {
+	var sent []rtcp.Packet
+	s := NewSynchronizer()
+	s.SetRTCPWriter(func(pkts []rtcp.Packet) error {
+		sent = append(sent, pkts...)
+		return nil
+	})
+
+	track := &fakeTrack{
+		id:    "video",
+		kind:  webrtc.RTPCodecTypeVideo,
+		codec: webrtc.RTPCodecParameters{RTPCodecCapability: webrtc.RTPCodecCapability{ClockRate: 90000}},
+		ssrc:  42,
+	}
+	ts := s.AddTrack(track)
+
+	// start well away from zero, since lastKeyFrameReq's zero value must not look like "just sent"
+	now := int64(10 * time.Second)
+	step := func(d time.Duration) { now += int64(d) }
+
+	// first reset: PLI
+	ts.requestKeyFrame(now)
+	// still within PLIInterval: suppressed entirely
+	step(100 * time.Millisecond)
+	ts.requestKeyFrame(now)
+	if len(sent) != 1 {
+		t.Fatalf("expected rate limiting to suppress the second call, got %d packets sent", len(sent))
+	}
+
+	// past PLIInterval, still within FIREscalationTimeout: second PLI
+	step(500 * time.Millisecond)
+	ts.requestKeyFrame(now)
+
+	// past PLIInterval again, still broken within the escalation window: should now be FIR
+	step(500 * time.Millisecond)
+	ts.requestKeyFrame(now)
+
+	if len(sent) != 3 {
+		t.Fatalf("expected 3 keyframe requests sent, got %d", len(sent))
+	}
+	if _, ok := sent[0].(*rtcp.PictureLossIndication); !ok {
+		t.Fatalf("expected first request to be a PLI, got %T", sent[0])
+	}
+	if _, ok := sent[1].(*rtcp.PictureLossIndication); !ok {
+		t.Fatalf("expected second request to be a PLI, got %T", sent[1])
+	}
+	fir, ok := sent[2].(*rtcp.FullIntraRequest)
+	if !ok {
+		t.Fatalf("expected third request to escalate to FIR, got %T", sent[2])
+	}
+	if len(fir.FIR) != 1 || fir.FIR[0].SSRC != 42 || fir.FIR[0].SequenceNumber != 1 {
+		t.Fatalf("unexpected FIR contents: %+v", fir)
+	}
+}
+
+func TestRequestKeyFrameResetsEscalationAfterLongGap(t *testing.T) {
+	var sent []rtcp.Packet
+	s := NewSynchronizer()
+	s.SetRTCPWriter(func(pkts []rtcp.Packet) error {
+		sent = append(sent, pkts...)
+		return nil
+	})
+	s.SetPLIInterval(0) // isolate the escalation-timeout behavior from rate limiting
+
+	track := &fakeTrack{
+		id:    "video",
+		kind:  webrtc.RTPCodecTypeVideo,
+		codec: webrtc.RTPCodecParameters{RTPCodecCapability: webrtc.RTPCodecCapability{ClockRate: 90000}},
+		ssrc:  42,
+	}
+	ts := s.AddTrack(track)
+
+	var now int64
+	ts.requestKeyFrame(now) // PLI #1
+	now += int64(100 * time.Millisecond)
+	ts.requestKeyFrame(now) // PLI #2
+
+	// a long, healthy gap passes (e.g. the track recovered); a later reset should start over at PLI
+	now += int64(5 * time.Second)
+	ts.requestKeyFrame(now)
+
+	if len(sent) != 3 {
+		t.Fatalf("expected 3 keyframe requests sent, got %d", len(sent))
+	}
+	if _, ok := sent[2].(*rtcp.PictureLossIndication); !ok {
+		t.Fatalf("expected escalation state to reset after a long gap, got %T", sent[2])
+	}
+}
@@ -0,0 +1,124 @@
+package synchronizer
+
+import (
+	"math"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+const ntpEpochOffset = 2208988800 // seconds between the NTP and Unix epochs
+
+// TrackStats is a point-in-time snapshot of a TrackSynchronizer's jitter, loss and drift
+type TrackStats struct {
+	Jitter       time.Duration // RFC 3550 interarrival jitter
+	FractionLost float64       // fraction of packets lost since the last Stats() call, in [0, 1]
+	PacketsLost  uint32        // cumulative packets lost
+	HighestSeqNo uint32        // highest extended (cycle-aware) sequence number received
+	PTSDrift     time.Duration // drift between computed PTS and the sender-reported NTP at the last SR
+	RTT          time.Duration // last observed round-trip time, 0 if unknown
+}
+
+// trackStats accumulates the running counters a TrackSynchronizer needs to produce TrackStats.
+// All access is serialized by the owning TrackSynchronizer's mutex.
+type trackStats struct {
+	baseSeqSet bool
+	baseSeq    uint16
+	highestSeq uint16
+	cycles     uint32 // sequence number wraps, in units of 0x10000
+
+	packetsReceived uint64
+	expectedPrior   uint64
+	receivedPrior   uint64
+
+	lastArrivalRTP int64
+	lastTransit    int64
+	haveTransit    bool
+	jitterRTP      float64
+
+	lastSRNTPMid uint32 // middle 32 bits of the last SR's NTP time, used to match RR LSR
+	lastRTT      time.Duration
+}
+
+// update folds in a newly-accepted packet. seq and ts are already continuity-corrected (post
+// sequence-number offset / overflow adjustment), and rtpDuration is the track's current ns-per-tick.
+func (s *trackStats) update(seq uint16, ts int64, rtpDuration float64, now int64) {
+	if !s.baseSeqSet {
+		s.baseSeqSet = true
+		s.baseSeq = seq
+		s.highestSeq = seq
+	} else if udelta := seq - s.highestSeq; udelta < 0x8000 {
+		if seq < s.highestSeq {
+			s.cycles += 0x10000
+		}
+		s.highestSeq = seq
+	}
+	s.packetsReceived++
+
+	arrivalRTP := int64(math.Round(float64(now) / rtpDuration))
+	transit := arrivalRTP - ts
+	if s.haveTransit {
+		d := transit - s.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		s.jitterRTP += (float64(d) - s.jitterRTP) / 16
+	}
+	s.lastTransit = transit
+	s.haveTransit = true
+	s.lastArrivalRTP = arrivalRTP
+}
+
+// handleReceiverReport resolves an RTT from an RTCP RR's LSR/DLSR if it matches the last SR we
+// observed for this track's SSRC.
+func (s *trackStats) handleReceiverReport(report rtcp.ReceptionReport) {
+	if report.LastSenderReport == 0 || report.LastSenderReport != s.lastSRNTPMid {
+		return
+	}
+
+	nowMid := ntpMid32(time.Now())
+	rttUnits := int64(nowMid) - int64(report.LastSenderReport) - int64(report.Delay)
+	if rttUnits <= 0 {
+		return
+	}
+
+	s.lastRTT = time.Duration(float64(rttUnits) / 65536 * float64(time.Second))
+}
+
+// snapshot computes a TrackStats from the accumulated counters, resetting the interval counters
+// used for FractionLost
+func (s *trackStats) snapshot(rtpDuration float64, ptsDrift time.Duration) TrackStats {
+	extHighest := s.cycles + uint32(s.highestSeq)
+	expected := uint64(extHighest) - uint64(s.baseSeq) + 1
+
+	lost := int64(expected) - int64(s.packetsReceived)
+	if lost < 0 {
+		lost = 0
+	}
+
+	var fractionLost float64
+	expectedInterval := expected - s.expectedPrior
+	receivedInterval := s.packetsReceived - s.receivedPrior
+	if lostInterval := int64(expectedInterval) - int64(receivedInterval); expectedInterval > 0 && lostInterval > 0 {
+		fractionLost = float64(lostInterval) / float64(expectedInterval)
+	}
+	s.expectedPrior = expected
+	s.receivedPrior = s.packetsReceived
+
+	return TrackStats{
+		Jitter:       time.Duration(math.Round(s.jitterRTP * rtpDuration)),
+		FractionLost: fractionLost,
+		PacketsLost:  uint32(lost),
+		HighestSeqNo: extHighest,
+		PTSDrift:     ptsDrift,
+		RTT:          s.lastRTT,
+	}
+}
+
+// ntpMid32 returns the middle 32 bits of t expressed as an NTP timestamp, matching the format
+// used for LSR in RTCP sender/receiver reports.
+func ntpMid32(t time.Time) uint32 {
+	sec := uint64(t.Unix()+ntpEpochOffset) & 0xffff
+	frac := uint32(uint64(t.Nanosecond()) << 32 / 1e9)
+	return uint32(sec)<<16 | (frac >> 16)
+}
@@ -0,0 +1,89 @@
+package synchronizer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+)
+
+// TestTrackStatsJitterAccumulates checks the RFC 3550 interarrival jitter estimate (the
+// exponential moving average of the transit-time delta) against a hand-computed sequence.
+func TestTrackStatsJitterAccumulates(t *testing.T) {
+	var s trackStats
+	const rtpDuration = 1 // 1ns/tick, so arrivalRTP == now and the math stays easy to trace by hand
+
+	s.update(1, 0, rtpDuration, 0)       // transit 0
+	s.update(2, 1000, rtpDuration, 1010) // transit 10, d=10, jitterRTP = 0 + 10/16 = 0.625
+	s.update(3, 2000, rtpDuration, 2005) // transit 5, d=5, jitterRTP = 0.625 + (5-0.625)/16 = 0.8984375
+
+	stats := s.snapshot(rtpDuration, 0)
+	if stats.Jitter != 1 {
+		t.Fatalf("expected jitter to round to 1ns, got %v", stats.Jitter)
+	}
+}
+
+// TestTrackStatsFractionLostAndPacketsLost checks loss accounting across a single gap, both for
+// the interval fraction (reset on every snapshot) and the cumulative count (never reset).
+func TestTrackStatsFractionLostAndPacketsLost(t *testing.T) {
+	var s trackStats
+	const rtpDuration = 1
+
+	s.update(1, 0, rtpDuration, 0)
+	s.update(2, 1000, rtpDuration, 1000)
+	s.update(4, 3000, rtpDuration, 3000) // seq 3 never arrives
+
+	stats := s.snapshot(rtpDuration, 0)
+	if stats.PacketsLost != 1 {
+		t.Fatalf("expected 1 cumulative packet lost, got %d", stats.PacketsLost)
+	}
+	if stats.FractionLost != 0.25 {
+		t.Fatalf("expected fraction lost 0.25 (1 of 4 expected), got %v", stats.FractionLost)
+	}
+	if stats.HighestSeqNo != 4 {
+		t.Fatalf("expected highest seq 4, got %d", stats.HighestSeqNo)
+	}
+
+	// a second snapshot with no new packets should not report the same loss again
+	stats = s.snapshot(rtpDuration, 0)
+	if stats.FractionLost != 0 {
+		t.Fatalf("expected fraction lost to reset to 0 with no new packets, got %v", stats.FractionLost)
+	}
+	if stats.PacketsLost != 1 {
+		t.Fatalf("expected cumulative packets lost to stay at 1, got %d", stats.PacketsLost)
+	}
+}
+
+// TestTrackStatsHandleReceiverReportRTT checks that an RR's LSR/DLSR is resolved into an RTT when
+// it matches the last SR we recorded, accepting 15ms to 200ms around the 20ms sleep.
+func TestTrackStatsHandleReceiverReportRTT(t *testing.T) {
+	var s trackStats
+	s.lastSRNTPMid = ntpMid32(time.Now())
+
+	time.Sleep(20 * time.Millisecond)
+
+	s.handleReceiverReport(rtcp.ReceptionReport{
+		LastSenderReport: s.lastSRNTPMid,
+		Delay:            0,
+	})
+
+	if s.lastRTT < 15*time.Millisecond || s.lastRTT > 200*time.Millisecond {
+		t.Fatalf("expected RTT close to the 20ms sleep, got %v", s.lastRTT)
+	}
+}
+
+// TestTrackStatsHandleReceiverReportIgnoresMismatchedSR checks that an RR referencing a different
+// SR than the last one we recorded is ignored rather than producing a bogus RTT.
+func TestTrackStatsHandleReceiverReportIgnoresMismatchedSR(t *testing.T) {
+	var s trackStats
+	s.lastSRNTPMid = 12345
+
+	s.handleReceiverReport(rtcp.ReceptionReport{
+		LastSenderReport: 54321,
+		Delay:            1,
+	})
+
+	if s.lastRTT != 0 {
+		t.Fatalf("expected RTT to stay 0 for a mismatched SR, got %v", s.lastRTT)
+	}
+}
@@ -0,0 +1,180 @@
+package synchronizer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+
+	"github.com/livekit/mediatransportutil"
+)
+
+const (
+	defaultPLIInterval          = 500 * time.Millisecond
+	defaultFIREscalationTimeout = time.Second
+)
+
+// timeOffset is the last (NTP, RTP) pair reported in an RTCP SR for a given SSRC
+type timeOffset struct {
+	ntp time.Time
+	rtp uint32
+}
+
+// Synchronizer keeps track of every TrackSynchronizer added to a single
+// session and coordinates their start time so that PTS values across
+// tracks (e.g. audio and video from the same participant) stay aligned.
+type Synchronizer struct {
+	sync.Mutex
+
+	startedAt int64 // time of first packet received for any track, in unix nanoseconds
+
+	trackSynchronizers map[uint32]*TrackSynchronizer // by SSRC
+	timeOffsets        map[uint32]timeOffset         // by SSRC, last RTCP SR (NTP, RTP) pair
+
+	rtcpWriter func([]rtcp.Packet) error
+
+	// pliInterval is the minimum time between PLIs sent for the same SSRC. Defaults to 500ms.
+	// Set via SetPLIInterval.
+	pliInterval time.Duration
+	// firEscalationTimeout is the window after a keyframe request within which another unanswered
+	// request counts toward escalating to FIR. Defaults to 1s. Set via SetFIREscalationTimeout.
+	firEscalationTimeout time.Duration
+}
+
+// NewSynchronizer creates a Synchronizer used to keep multiple tracks in sync
+func NewSynchronizer() *Synchronizer {
+	return &Synchronizer{
+		trackSynchronizers:   make(map[uint32]*TrackSynchronizer),
+		timeOffsets:          make(map[uint32]timeOffset),
+		pliInterval:          defaultPLIInterval,
+		firEscalationTimeout: defaultFIREscalationTimeout,
+	}
+}
+
+// SetRTCPWriter sets the function used to send PLI/FIR keyframe requests back to the sender.
+// Until this is set, TrackSynchronizer will not request keyframes on its own.
+func (s *Synchronizer) SetRTCPWriter(w func([]rtcp.Packet) error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.rtcpWriter = w
+}
+
+// SetPLIInterval sets the minimum time between PLIs sent for the same SSRC
+func (s *Synchronizer) SetPLIInterval(d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.pliInterval = d
+}
+
+// SetFIREscalationTimeout sets the window after a keyframe request within which another unanswered
+// request counts toward escalating to FIR
+func (s *Synchronizer) SetFIREscalationTimeout(d time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.firEscalationTimeout = d
+}
+
+// rtcpConfig returns the current keyframe-request writer and timing knobs, for use by a
+// TrackSynchronizer deciding whether to send a PLI or FIR.
+func (s *Synchronizer) rtcpConfig() (func([]rtcp.Packet) error, time.Duration, time.Duration) {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.rtcpWriter, s.pliInterval, s.firEscalationTimeout
+}
+
+// AddTrack registers a new track and returns its TrackSynchronizer
+func (s *Synchronizer) AddTrack(track TrackRemote) *TrackSynchronizer {
+	t := newTrackSynchronizer(s, track)
+
+	s.Lock()
+	s.trackSynchronizers[uint32(track.SSRC())] = t
+	s.Unlock()
+
+	return t
+}
+
+// getOrSetStartedAt returns the session start time, setting it if this is the first track to start
+func (s *Synchronizer) getOrSetStartedAt(now int64) int64 {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.startedAt == 0 {
+		s.startedAt = now
+	}
+	return s.startedAt
+}
+
+// SetTimeOffset records the (NTP, RTP) pair from the most recent RTCP SR for ssrc, so that
+// downstream writers re-muxing or re-publishing that SSRC can stamp their own packets with
+// wall-clock-aligned timestamps without each needing to parse RTCP themselves.
+func (s *Synchronizer) SetTimeOffset(ssrc uint32, ntp uint64, rtp uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.timeOffsets[ssrc] = timeOffset{
+		ntp: mediatransportutil.NtpTime(ntp).Time(),
+		rtp: rtp,
+	}
+}
+
+// GetTimeOffset returns the last (NTP, RTP) pair recorded for ssrc via SetTimeOffset
+func (s *Synchronizer) GetTimeOffset(ssrc uint32) (ntp time.Time, rtp uint32, ok bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	off, ok := s.timeOffsets[ssrc]
+	return off.ntp, off.rtp, ok
+}
+
+// SetRTCPReader registers a function used to pull incoming RTCP packets for this session. Once
+// set, Synchronizer reads from it for the lifetime of the session and forwards any Receiver
+// Reports to the matching TrackSynchronizer so its round-trip time can be reported in Stats().
+func (s *Synchronizer) SetRTCPReader(r func() ([]rtcp.Packet, error)) {
+	go s.readRTCP(r)
+}
+
+func (s *Synchronizer) readRTCP(r func() ([]rtcp.Packet, error)) {
+	for {
+		pkts, err := r()
+		if err != nil {
+			return
+		}
+
+		for _, pkt := range pkts {
+			rr, ok := pkt.(*rtcp.ReceiverReport)
+			if !ok {
+				continue
+			}
+			for _, report := range rr.Reports {
+				s.Lock()
+				t := s.trackSynchronizers[report.SSRC]
+				s.Unlock()
+
+				if t != nil {
+					t.HandleReceiverReport(report)
+				}
+			}
+		}
+	}
+}
+
+// Stats returns a snapshot of jitter, loss and drift stats for every track in the session, keyed
+// by track ID
+func (s *Synchronizer) Stats() map[string]TrackStats {
+	s.Lock()
+	tracks := make([]*TrackSynchronizer, 0, len(s.trackSynchronizers))
+	for _, t := range s.trackSynchronizers {
+		tracks = append(tracks, t)
+	}
+	s.Unlock()
+
+	stats := make(map[string]TrackStats, len(tracks))
+	for _, t := range tracks {
+		stats[t.trackID] = t.Stats()
+	}
+	return stats
+}
@@ -25,16 +25,29 @@ type TrackRemote interface {
 	SSRC() webrtc.SSRC
 }
 
+// CodecResolver looks up the codec parameters for an RTP payload type, so a TrackSynchronizer can
+// recompute its clock rate when the remote switches codecs on an already-open track.
+type CodecResolver interface {
+	ResolveCodec(payloadType uint8) (webrtc.RTPCodecParameters, bool)
+}
+
 type TrackSynchronizer struct {
 	sync.Mutex
 	sync *Synchronizer
 
 	// track info
 	trackID              string
+	ssrc                 uint32
+	kind                 webrtc.RTPCodecType
+	payloadType          uint8   // last seen RTP payload type, used to detect mid-stream codec changes
 	rtpDuration          float64 // duration in ns per unit RTP time
 	frameDuration        int64   // frame duration in RTP time
 	defaultFrameDuration int64   // used if no value has been recorded
 
+	codecResolver           CodecResolver
+	onPTChange              func(pt uint8, newCodec webrtc.RTPCodecParameters)
+	skipFrameDurationUpdate bool // set for one packet after a codec change, see handlePayloadTypeChange
+
 	// timing info
 	startedAt int64         // starting time in unix nanoseconds
 	firstTS   int64         // first RTP timestamp received
@@ -52,6 +65,18 @@ type TrackSynchronizer struct {
 	ptsOffset int64  // presentation timestamp offset (used for a/v sync)
 
 	lastPTSDrift time.Duration // track massive PTS drift, in case it's correct
+
+	jitterBuffer *jitterBuffer                                     // set by EnableJitterBuffer, reorders packets before GetPTS sees them
+	onNACK       func(ssrc uint32, firstSeq uint16, bitmap uint16) // set by OnNACK; survives EnableJitterBuffer in either call order
+
+	ntpStart time.Time // wall-clock time corresponding to pts 0, set from the first RTCP SR
+
+	// keyframe requests (video only)
+	lastKeyFrameReq   int64 // unix nanoseconds of the last PLI/FIR sent
+	consecutiveResets int   // keyframe requests sent since the last valid packet, drives FIR escalation
+	firSeqNo          uint8 // FIR command sequence number, incremented on every FIR sent
+
+	stats trackStats
 }
 
 func newTrackSynchronizer(s *Synchronizer, track TrackRemote) *TrackSynchronizer {
@@ -59,6 +84,9 @@ func newTrackSynchronizer(s *Synchronizer, track TrackRemote) *TrackSynchronizer
 
 	t := &TrackSynchronizer{
 		trackID:     track.ID(),
+		ssrc:        uint32(track.SSRC()),
+		kind:        track.Kind(),
+		payloadType: uint8(track.Codec().PayloadType),
 		sync:        s,
 		rtpDuration: float64(1000000000) / clockRate,
 	}
@@ -87,19 +115,144 @@ func (t *TrackSynchronizer) Initialize(pkt *rtp.Packet) {
 	t.Unlock()
 }
 
+// EnableJitterBuffer turns on jitter buffering for this track. Packets passed to PushRTP will be
+// held for up to depth before being released in sequence-number order, so that reordered packets
+// are resolved instead of triggering a sequence-number reset, and genuine losses can be NACKed.
+func (t *TrackSynchronizer) EnableJitterBuffer(depth time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.jitterBuffer = newJitterBuffer(t.ssrc, depth)
+	t.jitterBuffer.onNACK = t.onNACK
+}
+
+// OnNACK sets a callback invoked whenever a packet pushed into the jitter buffer reveals a gap of
+// still-missing sequence numbers before it. firstSeq is the first missing sequence number, and bitmap is a
+// standard RTCP NACK follow-up bitmap for up to 16 additional missing seqs after firstSeq.
+// OnNACK and EnableJitterBuffer can be called in either order.
+func (t *TrackSynchronizer) OnNACK(f func(ssrc uint32, firstSeq uint16, bitmap uint16)) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.onNACK = f
+	if t.jitterBuffer != nil {
+		t.jitterBuffer.onNACK = f
+	}
+}
+
+// PushRTP feeds a packet through the jitter buffer, if enabled, and returns the packets that are
+// now ready to be handed to GetPTS, in sequence-number order. If jitter buffering is not enabled,
+// it returns the packet unchanged.
+func (t *TrackSynchronizer) PushRTP(pkt *rtp.Packet) []*rtp.Packet {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.jitterBuffer == nil {
+		return []*rtp.Packet{pkt}
+	}
+
+	now := time.Now().UnixNano()
+	t.jitterBuffer.push(pkt, now)
+	return t.jitterBuffer.pop(now)
+}
+
+// FlushJitterBuffer releases every packet still held in the jitter buffer, in sequence-number
+// order, ignoring depth. Call this once the track has reached EOS, since no further packets will
+// arrive to drive the buffer's normal pop and age the tail out on its own.
+func (t *TrackSynchronizer) FlushJitterBuffer() []*rtp.Packet {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.jitterBuffer == nil {
+		return nil
+	}
+	return t.jitterBuffer.flush()
+}
+
+// SetCodecResolver supplies the CodecResolver used to look up the codec parameters for a track's
+// RTP payload type. It must be set before GetPTS can react to a mid-stream codec change.
+func (t *TrackSynchronizer) SetCodecResolver(r CodecResolver) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.codecResolver = r
+}
+
+// OnPayloadTypeChange sets a callback invoked whenever GetPTS detects that the remote has
+// switched codecs on this track's SSRC, after the new clock rate has already taken effect.
+func (t *TrackSynchronizer) OnPayloadTypeChange(f func(pt uint8, newCodec webrtc.RTPCodecParameters)) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.onPTChange = f
+}
+
+// handlePayloadTypeChange recomputes rtpDuration and defaultFrameDuration for the new codec and
+// rebases firstTS so that PTS stays monotonic across the transition. t must already be locked.
+func (t *TrackSynchronizer) handlePayloadTypeChange(pkt *rtp.Packet) {
+	if t.codecResolver == nil {
+		return
+	}
+
+	codec, ok := t.codecResolver.ResolveCodec(pkt.PayloadType)
+	if !ok || codec.ClockRate == 0 {
+		return
+	}
+
+	clockRate := float64(codec.ClockRate)
+	t.rtpDuration = float64(1000000000) / clockRate
+	t.frameDuration = 0 // stale, recomputed from the new clock rate once we see two in-order packets
+
+	switch t.kind {
+	case webrtc.RTPCodecTypeAudio:
+		t.defaultFrameDuration = int64(clockRate / 50)
+	default:
+		t.defaultFrameDuration = int64(clockRate / 24)
+	}
+
+	// rebase firstTS so that elapsed time computed from here picks up exactly where lastPTS left off
+	elapsed := int64(t.lastPTS) - t.ptsOffset
+	t.firstTS = int64(pkt.Timestamp) - int64(math.Round(float64(elapsed)/t.rtpDuration))
+	t.lastTS = int64(pkt.Timestamp) - 1 // dodge adjust's wrap loop/duplicate-ts shortcut, which assume the old clock rate
+
+	// ts - lastTS is meaningless across a clock-rate change, so skip the next frameDuration update
+	// rather than let it be overwritten with this bogus one-tick value
+	t.skipFrameDurationUpdate = true
+
+	t.payloadType = pkt.PayloadType
+
+	if t.onPTChange != nil {
+		t.onPTChange(pkt.PayloadType, codec)
+	}
+}
+
 // GetPTS will reset sequence numbers and/or offsets if necessary
 // Packets are expected to be in order
 func (t *TrackSynchronizer) GetPTS(pkt *rtp.Packet) (time.Duration, error) {
 	t.Lock()
 	defer t.Unlock()
 
+	if pkt.PayloadType != t.payloadType {
+		t.handlePayloadTypeChange(pkt)
+	}
+
+	now := time.Now().UnixNano()
+
 	ts, pts, valid := t.adjust(pkt)
 	t.inserted = 0
 
+	if valid {
+		t.consecutiveResets = 0
+		t.stats.update(pkt.SequenceNumber, ts, t.rtpDuration, now)
+	} else if t.kind == webrtc.RTPCodecTypeVideo {
+		t.requestKeyFrame(now)
+	}
+
 	// update frame duration if this is a new frame and both packets are valid
-	if valid && t.lastValid && pkt.SequenceNumber == t.lastSN+1 {
+	if valid && t.lastValid && pkt.SequenceNumber == t.lastSN+1 && !t.skipFrameDurationUpdate {
 		t.frameDuration = ts - t.lastTS
 	}
+	t.skipFrameDurationUpdate = false
 
 	// if past end time, return EOF
 	if t.maxPTS > 0 && (pts > t.maxPTS || !valid) {
@@ -155,6 +308,48 @@ func (t *TrackSynchronizer) getElapsed(ts int64) int64 {
 	return int64(math.Round(float64(ts-t.firstTS) * t.rtpDuration))
 }
 
+// requestKeyFrame sends a rate-limited PLI, escalating to FIR once two requests in a row have gone
+// unanswered (no valid packet since) and the next one comes within FIREscalationTimeout of the
+// last. A request after a longer gap starts over with a PLI. t must already be locked.
+func (t *TrackSynchronizer) requestKeyFrame(now int64) {
+	if t.sync == nil {
+		return
+	}
+
+	writer, pliInterval, firTimeout := t.sync.rtcpConfig()
+	if writer == nil {
+		return
+	}
+
+	if now-t.lastKeyFrameReq < int64(pliInterval) {
+		return
+	}
+	if now-t.lastKeyFrameReq >= int64(firTimeout) {
+		t.consecutiveResets = 0
+	}
+
+	escalate := t.consecutiveResets >= 2
+	t.lastKeyFrameReq = now
+	t.consecutiveResets++
+
+	var pkts []rtcp.Packet
+	if escalate {
+		t.firSeqNo++
+		pkts = []rtcp.Packet{&rtcp.FullIntraRequest{
+			SenderSSRC: t.ssrc,
+			MediaSSRC:  t.ssrc,
+			FIR:        []rtcp.FIREntry{{SSRC: t.ssrc, SequenceNumber: t.firSeqNo}},
+		}}
+	} else {
+		pkts = []rtcp.Packet{&rtcp.PictureLossIndication{
+			SenderSSRC: t.ssrc,
+			MediaSSRC:  t.ssrc,
+		}}
+	}
+
+	_ = writer(pkts)
+}
+
 // InsertFrame is used to inject frames (usually blank) into the stream
 // It updates the timestamp and sequence number of the packet, as well as offsets for all future packets
 func (t *TrackSynchronizer) InsertFrame(pkt *rtp.Packet) time.Duration {
@@ -235,9 +430,12 @@ func (t *TrackSynchronizer) onSenderReport(pkt *rtcp.SenderReport, pts time.Dura
 	t.Lock()
 	defer t.Unlock()
 
+	t.ntpStart = ntpStart
+
 	expected := mediatransportutil.NtpTime(pkt.NTPTime).Time().Sub(ntpStart)
+	drift := expected - pts
+	t.lastPTSDrift = drift
 	if pts != expected {
-		drift := expected - pts
 		// if absGreater(drift, largePTSDrift) {
 		// 	logger.Warnw("high pts drift", nil, "trackID", t.trackID, "pts", pts, "drift", drift)
 		// 	if absGreater(drift, massivePTSDrift) {
@@ -250,6 +448,53 @@ func (t *TrackSynchronizer) onSenderReport(pkt *rtcp.SenderReport, pts time.Dura
 
 		t.ptsOffset += int64(drift)
 	}
+
+	t.stats.lastSRNTPMid = uint32(pkt.NTPTime >> 16)
+
+	if t.sync != nil {
+		t.sync.SetTimeOffset(t.ssrc, pkt.NTPTime, pkt.RTPTime)
+	}
+}
+
+// GetNTPForPTS returns the wall-clock time corresponding to pts, derived from the most recent
+// RTCP SR for this track. ok is false until at least one SR has been received.
+func (t *TrackSynchronizer) GetNTPForPTS(pts time.Duration) (time.Time, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.ntpStart.IsZero() {
+		return time.Time{}, false
+	}
+	return t.ntpStart.Add(pts), true
+}
+
+// GetPTSForNTP is the inverse of GetNTPForPTS
+func (t *TrackSynchronizer) GetPTSForNTP(ntp time.Time) (time.Duration, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.ntpStart.IsZero() {
+		return 0, false
+	}
+	return ntp.Sub(t.ntpStart), true
+}
+
+// HandleReceiverReport updates the last observed round-trip time for this track from an RTCP RR's
+// LSR/DLSR, matched against the NTP timestamp of the last SR seen for this track's SSRC. It has no
+// effect until an SR has been seen. Synchronizer.SetRTCPReader calls it for every RR it reads.
+func (t *TrackSynchronizer) HandleReceiverReport(report rtcp.ReceptionReport) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.stats.handleReceiverReport(report)
+}
+
+// Stats returns a snapshot of this track's jitter, loss and drift stats
+func (t *TrackSynchronizer) Stats() TrackStats {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.stats.snapshot(t.rtpDuration, t.lastPTSDrift)
 }
 
 func absGreater(a, b time.Duration) bool {
@@ -0,0 +1,82 @@
+package synchronizer
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v3"
+)
+
+type fakeTrack struct {
+	id    string
+	codec webrtc.RTPCodecParameters
+	kind  webrtc.RTPCodecType
+	ssrc  webrtc.SSRC
+}
+
+func (f *fakeTrack) ID() string                       { return f.id }
+func (f *fakeTrack) Codec() webrtc.RTPCodecParameters { return f.codec }
+func (f *fakeTrack) Kind() webrtc.RTPCodecType        { return f.kind }
+func (f *fakeTrack) SSRC() webrtc.SSRC                { return f.ssrc }
+
+type fakeCodecResolver struct {
+	codecs map[uint8]webrtc.RTPCodecParameters
+}
+
+func (r *fakeCodecResolver) ResolveCodec(pt uint8) (webrtc.RTPCodecParameters, bool) {
+	c, ok := r.codecs[pt]
+	return c, ok
+}
+
+// TestHandlePayloadTypeChangeKeepsFrameDurationSane guards against the codec-switch packet
+// clobbering frameDuration with a bogus one-RTP-tick value (ts - lastTS across a rebased firstTS).
+func TestHandlePayloadTypeChangeKeepsFrameDurationSane(t *testing.T) {
+	const oldPT, newPT uint8 = 96, 97
+	const clockRate = 90000
+
+	track := &fakeTrack{
+		id:   "video",
+		kind: webrtc.RTPCodecTypeVideo,
+		codec: webrtc.RTPCodecParameters{
+			RTPCodecCapability: webrtc.RTPCodecCapability{ClockRate: clockRate},
+			PayloadType:        webrtc.PayloadType(oldPT),
+		},
+		ssrc: 1,
+	}
+
+	s := NewSynchronizer()
+	ts := s.AddTrack(track)
+	ts.SetCodecResolver(&fakeCodecResolver{codecs: map[uint8]webrtc.RTPCodecParameters{
+		newPT: {RTPCodecCapability: webrtc.RTPCodecCapability{ClockRate: clockRate}, PayloadType: webrtc.PayloadType(newPT)},
+	}})
+
+	pkt := &rtp.Packet{Header: rtp.Header{SequenceNumber: 1, Timestamp: 0, PayloadType: oldPT}}
+	ts.Initialize(pkt)
+	if _, err := ts.GetPTS(pkt); err != nil {
+		t.Fatalf("GetPTS: %v", err)
+	}
+
+	// a few contiguous frames, 3000 ticks (90kHz / 30fps) apart, to establish a real frameDuration
+	for i := uint16(2); i <= 4; i++ {
+		pkt = &rtp.Packet{Header: rtp.Header{SequenceNumber: i, Timestamp: uint32(i-1) * 3000, PayloadType: oldPT}}
+		if _, err := ts.GetPTS(pkt); err != nil {
+			t.Fatalf("GetPTS: %v", err)
+		}
+	}
+	if ts.frameDuration != 3000 {
+		t.Fatalf("expected frameDuration 3000 before codec change, got %d", ts.frameDuration)
+	}
+
+	// the codec switches on the very next, otherwise unremarkable, contiguous packet
+	pkt = &rtp.Packet{Header: rtp.Header{SequenceNumber: 5, Timestamp: 3*3000 + 1, PayloadType: newPT}}
+	if _, err := ts.GetPTS(pkt); err != nil {
+		t.Fatalf("GetPTS: %v", err)
+	}
+
+	if ts.frameDuration == 1 {
+		t.Fatalf("frameDuration clobbered with bogus one-tick value across codec switch")
+	}
+	if ts.frameDuration != 0 {
+		t.Fatalf("expected frameDuration to stay reset immediately after codec switch, got %d", ts.frameDuration)
+	}
+}